@@ -1,6 +1,11 @@
 package main
 
 import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sync"
+
 	"github.com/openshift/appliance/pkg/asset/appliance"
 	"github.com/openshift/appliance/pkg/asset/config"
 	"github.com/openshift/appliance/pkg/log"
@@ -11,8 +16,28 @@ import (
 	"github.com/pkg/errors"
 	"github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
+	"sigs.k8s.io/yaml"
 )
 
+// maxConcurrentArchBuilds bounds how many per-architecture disk images are
+// built in parallel, so a multi-arch build doesn't try to mirror every
+// release payload at once.
+const maxConcurrentArchBuilds = 2
+
+// buildManifest lists the output of a single-config, multi-architecture
+// build, so CI/tooling can discover each artifact and the release image
+// reference it was built from, exactly as resolved (not necessarily a digest
+// reference), without re-deriving it.
+type buildManifest struct {
+	Artifacts []buildManifestArtifact `json:"artifacts"`
+}
+
+type buildManifestArtifact struct {
+	CpuArchitecture string `json:"cpuArchitecture"`
+	ReleaseImage    string `json:"releaseImage"`
+	Path            string `json:"path"`
+}
+
 func NewBuildCmd() *cobra.Command {
 	cmd := &cobra.Command{
 		Use:    "build",
@@ -22,6 +47,7 @@ func NewBuildCmd() *cobra.Command {
 	}
 	cmd.Flags().BoolVar(&rootOpts.debugBootstrap, "debug-bootstrap", false, "")
 	cmd.Flags().BoolVar(&rootOpts.debugInstall, "debug-install", false, "")
+	cmd.Flags().BoolVar(&rootOpts.rotateToken, "rotate-token", false, "generate a new provisioning token, invalidating the previous one")
 	if err := cmd.Flags().MarkHidden("debug-bootstrap"); err != nil {
 		logrus.Fatal(err)
 	}
@@ -37,16 +63,150 @@ func runBuild(cmd *cobra.Command, args []string) {
 	cleanup := log.SetupFileHook(rootOpts.dir)
 	defer cleanup()
 
-	// Generate ApplianceDiskImage asset (including all of its dependencies)
-	applianceDiskImage := appliance.ApplianceDiskImage{}
-	if err := getAssetStore().Fetch(&applianceDiskImage); err != nil {
-		logrus.Fatal(errors.Wrapf(err, "failed to fetch %s", applianceDiskImage.Name()))
+	// Fetch ApplianceConfig up-front to discover the requested architectures;
+	// buildArch then fetches ApplianceDiskImage (and ApplianceConfig with it)
+	// from an asset store of its own for each architecture.
+	applianceConfig := config.ApplianceConfig{}
+	if err := getAssetStore().Fetch(&applianceConfig); err != nil {
+		logrus.Fatal(errors.Wrapf(err, "failed to fetch %s", applianceConfig.Name()))
+	}
+
+	manifest := buildManifest{}
+	archs := applianceConfig.GetCpuArchitectures()
+	if len(archs) == 1 {
+		manifest.Artifacts = append(manifest.Artifacts, buildArch(archs[0], &applianceConfig))
+	} else {
+		manifest.Artifacts = buildArchsInParallel(archs, &applianceConfig)
+	}
+
+	if err := writeBuildManifest(rootOpts.dir, manifest); err != nil {
+		logrus.Fatal(errors.Wrap(err, "failed to write build manifest"))
 	}
 
 	timer.StopTimer(timer.TotalTimeElapsed)
 	timer.LogSummary()
 
-	logrus.Infof("Appliance successfully created at assets directory: %s", templates.ApplianceFileName)
+	provisioningToken := config.ProvisioningToken{}
+	if err := getAssetStore().Fetch(&provisioningToken); err != nil {
+		logrus.Fatal(errors.Wrapf(err, "failed to fetch %s", provisioningToken.Name()))
+	}
+	logrus.Infof("Provisioning token: %s", provisioningToken.Token)
+
+	logrus.Infof("Appliance successfully created at assets directory: %s", rootOpts.dir)
+}
+
+// buildArchsInParallel fetches ApplianceDiskImage for every requested
+// architecture, bounded by maxConcurrentArchBuilds concurrent builds.
+func buildArchsInParallel(archs []string, applianceConfig *config.ApplianceConfig) []buildManifestArtifact {
+	artifacts := make([]buildManifestArtifact, len(archs))
+	sem := make(chan struct{}, maxConcurrentArchBuilds)
+	var wg sync.WaitGroup
+	for idx, arch := range archs {
+		wg.Add(1)
+		go func(idx int, arch string) {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+			artifacts[idx] = buildArch(arch, applianceConfig)
+		}(idx, arch)
+	}
+	wg.Wait()
+	return artifacts
+}
+
+// buildArch builds the ApplianceDiskImage for a single architecture. For a
+// multi-arch config, each architecture gets its own output subdirectory
+// *and* its own asset store rooted there, seeded with a derived
+// appliance-config.yaml pinned to that single architecture and its already
+// resolved release image. This is what actually makes ApplianceDiskImage
+// (and everything it depends on) build the right bits for arch: without it,
+// every per-arch store would fall back to CpuArchitectures[0], and sharing
+// one store across concurrent goroutines would race on its cached asset
+// state and on-disk files.
+func buildArch(arch string, applianceConfig *config.ApplianceConfig) buildManifestArtifact {
+	archDir := rootOpts.dir
+	if len(applianceConfig.GetCpuArchitectures()) > 1 {
+		archDir = filepath.Join(rootOpts.dir, arch)
+		if err := os.MkdirAll(archDir, os.ModePerm); err != nil {
+			logrus.Fatal(errors.Wrapf(err, "failed to create output directory for %s", arch))
+		}
+		if err := writeArchApplianceConfig(archDir, arch, applianceConfig); err != nil {
+			logrus.Fatal(errors.Wrapf(err, "failed to prepare appliance config for %s", arch))
+		}
+	}
+
+	assetStore, err := assetstore.NewStore(archDir)
+	if err != nil {
+		logrus.Fatal(errors.Wrap(err, "failed to create asset store"))
+	}
+	if err := assetStore.Fetch(&config.EnvConfig{
+		AssetsDir:      archDir,
+		DebugBootstrap: rootOpts.debugBootstrap,
+		DebugInstall:   rootOpts.debugInstall,
+	}); err != nil {
+		logrus.Fatal(err)
+	}
+
+	applianceDiskImage := appliance.ApplianceDiskImage{}
+	if err := assetStore.Fetch(&applianceDiskImage); err != nil {
+		logrus.Fatal(errors.Wrapf(err, "failed to fetch %s for %s", applianceDiskImage.Name(), arch))
+	}
+
+	return buildManifestArtifact{
+		CpuArchitecture: arch,
+		ReleaseImage:    applianceConfig.ReleaseImages[arch],
+		Path:            filepath.Join(archDir, templates.ApplianceFileName),
+	}
+}
+
+// writeArchApplianceConfig derives a single-architecture appliance-config.yaml
+// for arch from the already-loaded applianceConfig and writes it into
+// archDir, pinning ocpRelease.image to the release image already resolved
+// for arch so the per-arch store doesn't have to re-resolve it from scratch
+// (its architecture is still checked again when the per-arch store loads
+// the config). A relative userIgnition path, and the default overrides.ign,
+// are resolved against the top-level assets dir rather than archDir.
+func writeArchApplianceConfig(archDir, arch string, applianceConfig *config.ApplianceConfig) error {
+	var raw map[string]interface{}
+	if err := yaml.Unmarshal(applianceConfig.File.Data, &raw); err != nil {
+		return errors.Wrap(err, "failed to parse appliance config")
+	}
+
+	ocpRelease, _ := raw["ocpRelease"].(map[string]interface{})
+	if ocpRelease == nil {
+		ocpRelease = map[string]interface{}{}
+	}
+	ocpRelease["cpuArchitecture"] = arch
+	ocpRelease["source"] = string(config.ReleaseSourcePinned)
+	ocpRelease["image"] = applianceConfig.ReleaseImages[arch]
+	raw["ocpRelease"] = ocpRelease
+
+	assetsDir, err := filepath.Abs(filepath.Dir(archDir))
+	if err != nil {
+		return err
+	}
+	if userIgnition, _ := raw["userIgnition"].(string); userIgnition == "" {
+		if _, err := os.Stat(filepath.Join(assetsDir, "overrides.ign")); err == nil {
+			raw["userIgnition"] = filepath.Join(assetsDir, "overrides.ign")
+		}
+	} else if !filepath.IsAbs(userIgnition) {
+		raw["userIgnition"] = filepath.Join(assetsDir, userIgnition)
+	}
+
+	data, err := yaml.Marshal(raw)
+	if err != nil {
+		return errors.Wrap(err, "failed to render appliance config")
+	}
+
+	return os.WriteFile(filepath.Join(archDir, config.ApplianceConfigFilename), data, 0644)
+}
+
+func writeBuildManifest(directory string, manifest buildManifest) error {
+	data, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(directory, "manifest.json"), data, 0644)
 }
 
 func preRunBuild(cmd *cobra.Command, args []string) {
@@ -58,6 +218,14 @@ func preRunBuild(cmd *cobra.Command, args []string) {
 	}); err != nil {
 		logrus.Fatal(err)
 	}
+
+	if rootOpts.rotateToken {
+		tokenPath := filepath.Join(rootOpts.dir, config.ProvisioningTokenFilename)
+		if err := os.Remove(tokenPath); err != nil && !os.IsNotExist(err) {
+			logrus.Fatal(errors.Wrap(err, "failed to remove existing provisioning token"))
+		}
+		logrus.Debug("Rotating provisioning token")
+	}
 }
 
 func getAssetStore() asset.Store {
@@ -0,0 +1,69 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/openshift/appliance/pkg/asset/config"
+	"github.com/openshift/installer/pkg/asset"
+	"sigs.k8s.io/yaml"
+)
+
+// TestWriteArchApplianceConfigPinsPerArchImage guards against a multi-arch
+// build silently writing the same release image into every architecture's
+// output directory: each arch's derived config must be pinned to that arch's
+// own already-resolved release image, not some other arch's.
+func TestWriteArchApplianceConfigPinsPerArchImage(t *testing.T) {
+	rawConfig := []byte(`
+apiVersion: v1beta1
+kind: ApplianceConfig
+ocpRelease:
+  version: "4.14"
+  cpuArchitecture: [x86_64, aarch64]
+  source: mirror
+  mirror: mirror.example.com
+diskSizeGB: 200
+pullSecret: '{}'
+`)
+
+	applianceConfig := &config.ApplianceConfig{
+		File: &asset.File{Data: rawConfig},
+		ReleaseImages: map[string]string{
+			config.CpuArchitectureX86:     "mirror.example.com/openshift/release-images@sha256:x86",
+			config.CpuArchitectureAARCH64: "mirror.example.com/openshift/release-images@sha256:aarch64",
+		},
+	}
+
+	dir := t.TempDir()
+	if err := writeArchApplianceConfig(dir, config.CpuArchitectureAARCH64, applianceConfig); err != nil {
+		t.Fatalf("writeArchApplianceConfig failed: %v", err)
+	}
+
+	data, err := os.ReadFile(filepath.Join(dir, config.ApplianceConfigFilename))
+	if err != nil {
+		t.Fatalf("failed to read derived config: %v", err)
+	}
+
+	var derived struct {
+		OcpRelease struct {
+			CpuArchitecture string `json:"cpuArchitecture"`
+			Source          string `json:"source"`
+			Image           string `json:"image"`
+		} `json:"ocpRelease"`
+	}
+	if err := yaml.Unmarshal(data, &derived); err != nil {
+		t.Fatalf("failed to parse derived config: %v", err)
+	}
+
+	if derived.OcpRelease.CpuArchitecture != config.CpuArchitectureAARCH64 {
+		t.Errorf("cpuArchitecture = %q, want %q", derived.OcpRelease.CpuArchitecture, config.CpuArchitectureAARCH64)
+	}
+	if derived.OcpRelease.Source != string(config.ReleaseSourcePinned) {
+		t.Errorf("source = %q, want %q", derived.OcpRelease.Source, config.ReleaseSourcePinned)
+	}
+	wantImage := applianceConfig.ReleaseImages[config.CpuArchitectureAARCH64]
+	if derived.OcpRelease.Image != wantImage {
+		t.Errorf("image = %q, want %q (not the x86_64 image)", derived.OcpRelease.Image, wantImage)
+	}
+}
@@ -0,0 +1,60 @@
+package main
+
+import (
+	"os"
+
+	"github.com/go-openapi/swag"
+	"github.com/openshift/appliance/pkg/asset/config"
+	assetstore "github.com/openshift/installer/pkg/asset/store"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+)
+
+const (
+	exitCodeConfigError   = 2
+	exitCodeRegistryError = 3
+)
+
+// NewValidateCmd creates the `appliance validate` subcommand, which dry-runs
+// config and release resolution without downloading the release payload or
+// writing ignition, so that trivial pullSecret/apiVersion/cpuArchitecture
+// mistakes fail fast instead of only surfacing after a long build.
+func NewValidateCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "validate",
+		Short: "validate an appliance-config.yaml without building an appliance",
+		Run:   runValidate,
+	}
+	return cmd
+}
+
+func init() {
+	rootCmd.AddCommand(NewValidateCmd())
+}
+
+func runValidate(cmd *cobra.Command, args []string) {
+	applianceConfig := config.ApplianceConfig{}
+	store, err := assetstore.NewStore(rootOpts.dir)
+	if err != nil {
+		logrus.Error(errors.Wrap(err, "failed to create asset store"))
+		os.Exit(exitCodeRegistryError)
+	}
+
+	if err := store.Fetch(&applianceConfig); err != nil {
+		var configErr *config.ConfigError
+		if errors.As(err, &configErr) {
+			logrus.Error(configErr)
+			os.Exit(exitCodeConfigError)
+		}
+		logrus.Error(errors.Wrap(err, "failed to resolve release image"))
+		os.Exit(exitCodeRegistryError)
+	}
+
+	releaseArch := config.GetReleaseArchitectureByCPU(applianceConfig.GetCpuArchitecture())
+	logrus.Info("appliance-config.yaml is valid")
+	logrus.Infof("Release image URL: %s", swag.StringValue(applianceConfig.Config.OcpRelease.URL))
+	logrus.Infof("Release version: %s", applianceConfig.Config.OcpRelease.Version)
+	logrus.Infof("Release channel: %s", applianceConfig.Config.OcpRelease.Channel)
+	logrus.Infof("Architecture: %s (%s)", applianceConfig.GetCpuArchitecture(), releaseArch)
+}
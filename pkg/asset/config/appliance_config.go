@@ -7,7 +7,6 @@ import (
 	"strings"
 
 	"github.com/go-openapi/swag"
-	"github.com/openshift/appliance/pkg/graph"
 	"github.com/openshift/appliance/pkg/types"
 	"github.com/openshift/installer/pkg/asset"
 	"github.com/openshift/installer/pkg/validate"
@@ -40,6 +39,26 @@ type ApplianceConfig struct {
 	File     *asset.File
 	Config   *types.ApplianceConfig
 	Template string
+
+	// CpuArchitectures holds every CPU architecture declared in
+	// ocpRelease.cpuArchitecture, in declaration order. Config.OcpRelease.URL
+	// and .Version only ever reflect CpuArchitectures[0], for backwards
+	// compatibility with single-arch consumers; use ReleaseImages to look up
+	// the resolved release image for any other architecture.
+	CpuArchitectures []string
+	ReleaseImages    map[string]string
+
+	// UserIgnitionPath holds the value of the top-level userIgnition field,
+	// parsed out-of-band (like CpuArchitectures) since it isn't a field on
+	// types.ApplianceConfig and yaml.UnmarshalStrict would otherwise reject
+	// it as an unknown key.
+	UserIgnitionPath string
+
+	// verifiedArchitectures holds the architectures the resolver already
+	// confirmed via the release image's own metadata while resolving it
+	// (see ReleaseResolver). finish() skips its own, redundant
+	// validateReleaseArchitecture check for these.
+	verifiedArchitectures map[string]bool
 }
 
 var _ asset.WritableAsset = (*ApplianceConfig)(nil)
@@ -73,9 +92,19 @@ ocpRelease:
 	# [Optional] 
 	channel: ocp-release-channel
 	# OCP release CPU architecture: x86_64|aarch64|ppc64le
+	# May also be a list (e.g. [x86_64, aarch64]) to build one appliance
+	# image per architecture from this config.
 	# Default: x86_64
 	# [Optional]
 	cpuArchitecture: cpu-architecture
+	# Where to resolve the release image from: graph|mirror|oci|pinned
+	# graph: the Cincinnati update graph (default)
+	# mirror: a mirror registry reachable at ocpRelease.mirror
+	# oci: a local filesystem OCI image layout directory at ocpRelease.ociDir
+	# pinned: the image pinned at ocpRelease.image, used as-is
+	# Default: graph
+	# [Optional]
+	source: release-source
 # Virtual size of the appliance disk image
 diskSizeGB: disk-size
 # PullSecret required for mirroring the OCP release payload
@@ -83,6 +112,13 @@ pullSecret: pull-secret
 # Public SSH key for accessing the appliance
 # [Optional]
 sshKey: ssh-key
+# Path to a user-supplied ignition config file (butane is not accepted)
+# that will be merged into the appliance's install ignition, on top of
+# the base and generated install ignition
+# (e.g. to add NetworkManager keyfiles, chrony.conf, or extra SSH keys)
+# Defaults to overrides.ign in the assets dir if present.
+# [Optional]
+userIgnition: user-ignition-path
 `
 	a.Template = applianceConfigTemplate
 
@@ -128,27 +164,67 @@ func (a *ApplianceConfig) Load(f asset.FileFetcher) (bool, error) {
 		return false, errors.Wrapf(err, "failed to unmarshal %s", ApplianceConfigFilename)
 	}
 
-	// Fallback to x86_64
-	if config.OcpRelease.CpuArchitecture == nil {
-		config.OcpRelease.CpuArchitecture = swag.String(CpuArchitectureX86)
+	archs, err := parseCpuArchitectures(file.Data)
+	if err != nil {
+		return false, &ConfigError{errors.Wrapf(err, "failed to parse %s", ApplianceConfigFilename)}
 	}
 
-	cpuArch := strings.ToLower(*config.OcpRelease.CpuArchitecture)
-	if !funk.Contains(cpuArchitectures, cpuArch) {
-		return false, errors.Errorf("Unsupported CPU architecture: %s", cpuArch)
+	cpuArchitecturesList := make([]string, 0, len(archs))
+	seenArchs := map[string]bool{}
+	for _, arch := range archs {
+		arch = strings.ToLower(arch)
+		if !funk.Contains(cpuArchitectures, arch) {
+			return false, &ConfigError{errors.Errorf("Unsupported CPU architecture: %s", arch)}
+		}
+		if seenArchs[arch] {
+			continue
+		}
+		seenArchs[arch] = true
+		cpuArchitecturesList = append(cpuArchitecturesList, arch)
 	}
-	config.OcpRelease.CpuArchitecture = swag.String(cpuArch)
-	releaseArch := GetReleaseArchitectureByCPU(cpuArch)
+	if len(cpuArchitecturesList) == 0 {
+		return false, &ConfigError{errors.New("ocpRelease.cpuArchitecture must specify at least one architecture")}
+	}
+	config.OcpRelease.CpuArchitecture = swag.String(cpuArchitecturesList[0])
 
-	g := graph.NewGraph()
-	releaseImage, releaseVersion, err := g.GetReleaseImage(config.OcpRelease.Version, config.OcpRelease.Channel, releaseArch)
+	resolverConfig, err := parseReleaseResolverConfig(file.Data)
 	if err != nil {
-		return false, err
+		return false, &ConfigError{errors.Wrapf(err, "failed to parse %s", ApplianceConfigFilename)}
+	}
+	resolver, err := newReleaseResolver(resolverConfig, config.PullSecret)
+	if err != nil {
+		return false, &ConfigError{err}
+	}
+
+	releaseImages := make(map[string]string, len(cpuArchitecturesList))
+	verifiedArchitectures := make(map[string]bool, len(cpuArchitecturesList))
+	releaseVersion := config.OcpRelease.Version
+	for _, arch := range cpuArchitecturesList {
+		releaseImage, resolvedVersion, resolvedArch, err := resolver.Resolve(config.OcpRelease.Version, config.OcpRelease.Channel, GetReleaseArchitectureByCPU(arch))
+		if err != nil {
+			return false, err
+		}
+		releaseImages[arch] = releaseImage
+		if arch == cpuArchitecturesList[0] {
+			releaseVersion = resolvedVersion
+		}
+		if resolvedArch != "" {
+			verifiedArchitectures[arch] = true
+		}
 	}
-	config.OcpRelease.URL = &releaseImage
+	config.OcpRelease.URL = swag.String(releaseImages[cpuArchitecturesList[0]])
 	config.OcpRelease.Version = releaseVersion
 
+	userIgnitionPath, err := parseUserIgnitionPath(file.Data)
+	if err != nil {
+		return false, &ConfigError{errors.Wrapf(err, "failed to parse %s", ApplianceConfigFilename)}
+	}
+
 	a.File, a.Config = file, config
+	a.CpuArchitectures = cpuArchitecturesList
+	a.ReleaseImages = releaseImages
+	a.UserIgnitionPath = userIgnitionPath
+	a.verifiedArchitectures = verifiedArchitectures
 	if err = a.finish(); err != nil {
 		return false, err
 	}
@@ -156,9 +232,31 @@ func (a *ApplianceConfig) Load(f asset.FileFetcher) (bool, error) {
 	return true, nil
 }
 
+// ConfigError indicates the appliance-config.yaml contents themselves are
+// invalid, as opposed to a network/registry failure encountered while
+// resolving or validating the release image. NewValidateCmd uses this
+// distinction to choose an exit code.
+type ConfigError struct{ error }
+
 func (a *ApplianceConfig) finish() error {
 	if err := a.validateConfig().ToAggregate(); err != nil {
-		return errors.Wrapf(err, "invalid Appliance Config configuration")
+		return &ConfigError{errors.Wrapf(err, "invalid Appliance Config configuration")}
+	}
+
+	for _, cpuArch := range a.GetCpuArchitectures() {
+		if a.verifiedArchitectures[cpuArch] {
+			// Already confirmed by the resolver itself; skip a redundant
+			// oc adm release info round trip.
+			continue
+		}
+		releaseArch := GetReleaseArchitectureByCPU(cpuArch)
+		if err := validateReleaseArchitecture(a.ReleaseImages[cpuArch], a.Config.PullSecret, releaseArch); err != nil {
+			wrapped := errors.Wrapf(field.ErrorList{err}.ToAggregate(), "invalid Appliance Config configuration")
+			if err.Type == field.ErrorTypeInternal {
+				return wrapped
+			}
+			return &ConfigError{wrapped}
+		}
 	}
 
 	return nil
@@ -188,6 +286,29 @@ func (a *ApplianceConfig) GetCpuArchitecture() string {
 	return swag.StringValue(a.Config.OcpRelease.CpuArchitecture)
 }
 
+// GetCpuArchitectures returns every CPU architecture declared in
+// ocpRelease.cpuArchitecture, in declaration order.
+func (a *ApplianceConfig) GetCpuArchitectures() []string {
+	if len(a.CpuArchitectures) == 0 {
+		return []string{a.GetCpuArchitecture()}
+	}
+	return a.CpuArchitectures
+}
+
+// parseUserIgnitionPath reads the top-level userIgnition field from the raw
+// appliance-config.yaml contents. It's parsed out-of-band, the same way as
+// ocpRelease.cpuArchitecture, rather than added to types.ApplianceConfig, so
+// that yaml.UnmarshalStrict in Load doesn't reject it as an unknown key.
+func parseUserIgnitionPath(raw []byte) (string, error) {
+	var holder struct {
+		UserIgnition string `json:"userIgnition"`
+	}
+	if err := yaml.Unmarshal(raw, &holder); err != nil {
+		return "", errors.Wrap(err, "failed to parse userIgnition")
+	}
+	return holder.UserIgnition, nil
+}
+
 func GetReleaseArchitectureByCPU(arch string) string {
 	switch arch {
 	case CpuArchitectureX86:
@@ -0,0 +1,26 @@
+package config
+
+import "testing"
+
+func TestParseUserIgnitionPath(t *testing.T) {
+	cases := []struct {
+		name string
+		raw  string
+		want string
+	}{
+		{name: "absent", raw: `apiVersion: v1beta1`, want: ""},
+		{name: "present", raw: "userIgnition: /tmp/overrides.ign", want: "/tmp/overrides.ign"},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := parseUserIgnitionPath([]byte(tc.raw))
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got != tc.want {
+				t.Fatalf("got %q, want %q", got, tc.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,43 @@
+package config
+
+import (
+	"encoding/json"
+
+	"github.com/pkg/errors"
+	"sigs.k8s.io/yaml"
+)
+
+// parseCpuArchitectures reads ocpRelease.cpuArchitecture from the raw
+// appliance-config.yaml contents, accepting either a single string (the
+// traditional scalar form) or a list of strings (for multi-architecture
+// builds), and returns the architectures in the order they were declared.
+// Defaults to CpuArchitectureX86 when the field is absent.
+func parseCpuArchitectures(raw []byte) ([]string, error) {
+	var holder struct {
+		OcpRelease struct {
+			CpuArchitecture json.RawMessage `json:"cpuArchitecture"`
+		} `json:"ocpRelease"`
+	}
+	if err := yaml.Unmarshal(raw, &holder); err != nil {
+		return nil, errors.Wrap(err, "failed to parse ocpRelease.cpuArchitecture")
+	}
+
+	// Field absent leaves CpuArchitecture empty; field explicitly set to
+	// `null` leaves it holding the 4-byte JSON literal "null" instead, which
+	// json.Unmarshal would otherwise happily decode into an empty []string.
+	// Both mean "not specified", so both default the same way.
+	if len(holder.OcpRelease.CpuArchitecture) == 0 || string(holder.OcpRelease.CpuArchitecture) == "null" {
+		return []string{CpuArchitectureX86}, nil
+	}
+
+	var list []string
+	if err := json.Unmarshal(holder.OcpRelease.CpuArchitecture, &list); err == nil {
+		return list, nil
+	}
+
+	var single string
+	if err := json.Unmarshal(holder.OcpRelease.CpuArchitecture, &single); err != nil {
+		return nil, errors.New("ocpRelease.cpuArchitecture must be a string or a list of strings")
+	}
+	return []string{single}, nil
+}
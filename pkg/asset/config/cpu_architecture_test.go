@@ -0,0 +1,59 @@
+package config
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseCpuArchitectures(t *testing.T) {
+	cases := []struct {
+		name    string
+		raw     string
+		want    []string
+		wantErr bool
+	}{
+		{
+			name: "absent field defaults to x86_64",
+			raw:  `ocpRelease: {}`,
+			want: []string{CpuArchitectureX86},
+		},
+		{
+			name: "scalar string",
+			raw:  "ocpRelease:\n  cpuArchitecture: aarch64",
+			want: []string{"aarch64"},
+		},
+		{
+			name: "list of strings",
+			raw:  "ocpRelease:\n  cpuArchitecture: [x86_64, aarch64]",
+			want: []string{"x86_64", "aarch64"},
+		},
+		{
+			name: "empty list",
+			raw:  "ocpRelease:\n  cpuArchitecture: []",
+			want: []string{},
+		},
+		{
+			name: "null",
+			raw:  "ocpRelease:\n  cpuArchitecture: null",
+			want: []string{CpuArchitectureX86},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := parseCpuArchitectures([]byte(tc.raw))
+			if tc.wantErr {
+				if err == nil {
+					t.Fatalf("expected an error, got none")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(got, tc.want) {
+				t.Fatalf("got %v, want %v", got, tc.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,91 @@
+package config
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"os"
+	"path/filepath"
+
+	"github.com/openshift/installer/pkg/asset"
+	"github.com/pkg/errors"
+)
+
+// ProvisioningTokenFilename is where the provisioning token is persisted in
+// the assets dir, so that it survives across re-runs of the build and is
+// reused by subsequent ignition generation until rotated (see
+// `appliance build --rotate-token`, which removes this file up-front).
+const ProvisioningTokenFilename = ".provisioning-token"
+
+const provisioningTokenBytes = 32
+
+// ProvisioningToken is a randomly generated token that the base ignition
+// carries in a Kubernetes Secret manifest (see ignition.BaseIgnition). No
+// service that checks the token before serving the machine ignition is set
+// up alongside it.
+type ProvisioningToken struct {
+	File  *asset.File
+	Token string
+}
+
+var _ asset.WritableAsset = (*ProvisioningToken)(nil)
+
+// Name returns the human friendly name of the asset.
+func (*ProvisioningToken) Name() string {
+	return "Provisioning Token"
+}
+
+// Dependencies returns all the dependencies directly needed to generate
+// the asset.
+func (*ProvisioningToken) Dependencies() []asset.Asset {
+	return []asset.Asset{}
+}
+
+// Generate generates a new random provisioning token.
+func (t *ProvisioningToken) Generate(dependencies asset.Parents) error {
+	token, err := generateProvisioningToken()
+	if err != nil {
+		return errors.Wrap(err, "failed to generate provisioning token")
+	}
+	t.Token = token
+
+	return nil
+}
+
+// PersistToFile writes the provisioning token to the assets folder.
+func (t *ProvisioningToken) PersistToFile(directory string) error {
+	tokenPath := filepath.Join(directory, ProvisioningTokenFilename)
+	return os.WriteFile(tokenPath, []byte(t.Token), 0600)
+}
+
+// Files returns the files generated by the asset.
+func (t *ProvisioningToken) Files() []*asset.File {
+	if t.File != nil {
+		return []*asset.File{t.File}
+	}
+	return []*asset.File{}
+}
+
+// Load reads a previously persisted provisioning token from disk, if one
+// exists. Rotation is handled by the build command removing the persisted
+// file up-front, which causes this to fall through to Generate.
+func (t *ProvisioningToken) Load(f asset.FileFetcher) (bool, error) {
+	file, err := f.FetchByName(ProvisioningTokenFilename)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return false, nil
+		}
+		return false, errors.Wrap(err, "failed to load provisioning token file")
+	}
+
+	t.File, t.Token = file, string(file.Data)
+
+	return true, nil
+}
+
+func generateProvisioningToken() (string, error) {
+	buf := make([]byte, provisioningTokenBytes)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(buf), nil
+}
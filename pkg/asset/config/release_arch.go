@@ -0,0 +1,121 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+
+	"github.com/pkg/errors"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+)
+
+// releaseInfo is the subset of `oc adm release info -o=json` output we care
+// about when checking the architecture of a release image.
+type releaseInfo struct {
+	Metadata struct {
+		Metadata map[string]string `json:"metadata"`
+	} `json:"metadata"`
+	Config struct {
+		Architecture string `json:"architecture"`
+	} `json:"config"`
+	Manifests []struct {
+		Platform struct {
+			Architecture string `json:"architecture"`
+		} `json:"platform"`
+	} `json:"manifests"`
+}
+
+const releaseArchitectureLabel = "release.openshift.io/architecture"
+
+// validateReleaseArchitecture ensures the release image referenced by
+// releaseImage actually matches releaseArch. It shells out to
+// `oc adm release info` (authenticated with pullSecret) rather than
+// trusting the graph-derived URL, since a user-supplied ocpRelease.URL or a
+// mismatched multi-arch payload could otherwise slip through undetected
+// until much later in the build.
+func validateReleaseArchitecture(releaseImage, pullSecret, releaseArch string) *field.Error {
+	info, err := fetchReleaseInfo(releaseImage, pullSecret)
+	if err != nil {
+		return field.InternalError(field.NewPath("ocpRelease", "url"), err)
+	}
+	return matchReleaseInfoArch(info, releaseImage, releaseArch)
+}
+
+// fetchReleaseInfo shells out to `oc adm release info` (authenticated with
+// pullSecret) and parses its JSON output. Resolvers that already need to
+// inspect a release image's contents (e.g. to confirm its architecture) call
+// this directly rather than going through validateReleaseArchitecture, so
+// the image is only inspected once.
+func fetchReleaseInfo(releaseImage, pullSecret string) (releaseInfo, error) {
+	out, err := runOcAdmReleaseInfo(releaseImage, pullSecret)
+	if err != nil {
+		return releaseInfo{}, errors.Wrapf(err, "failed to inspect release image %q", releaseImage)
+	}
+
+	var info releaseInfo
+	if err := json.Unmarshal(out, &info); err != nil {
+		return releaseInfo{}, errors.Wrapf(err, "failed to parse release info for %q", releaseImage)
+	}
+	return info, nil
+}
+
+// matchReleaseInfoArch checks that info, the already-fetched release info
+// for releaseImage, matches releaseArch.
+func matchReleaseInfoArch(info releaseInfo, releaseImage, releaseArch string) *field.Error {
+	// Multi-arch release image: verify the requested architecture is one
+	// of the manifests in the list.
+	if len(info.Manifests) > 0 {
+		for _, m := range info.Manifests {
+			if m.Platform.Architecture == releaseArch {
+				return nil
+			}
+		}
+		return field.Invalid(field.NewPath("ocpRelease", "cpuArchitecture"), releaseArch,
+			fmt.Sprintf("release image %q is a multi-arch payload that does not include architecture %q", releaseImage, releaseArch))
+	}
+
+	actualArch := info.Metadata.Metadata[releaseArchitectureLabel]
+	if actualArch == "" {
+		actualArch = info.Config.Architecture
+	}
+	if actualArch == "" {
+		// Architecture could not be determined from the payload; nothing to validate against.
+		return nil
+	}
+	if actualArch != releaseArch {
+		return field.Invalid(field.NewPath("ocpRelease", "cpuArchitecture"), releaseArch,
+			fmt.Sprintf("release image %q has architecture %q, which does not match the configured architecture", releaseImage, actualArch))
+	}
+
+	return nil
+}
+
+func runOcAdmReleaseInfo(releaseImage, pullSecret string) ([]byte, error) {
+	pullSecretFile, err := os.CreateTemp("", "appliance-pull-secret-*.json")
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to create temporary pull secret file")
+	}
+	defer os.Remove(pullSecretFile.Name())
+	if _, err := pullSecretFile.WriteString(pullSecret); err != nil {
+		pullSecretFile.Close()
+		return nil, errors.Wrap(err, "failed to write temporary pull secret file")
+	}
+	if err := pullSecretFile.Close(); err != nil {
+		return nil, err
+	}
+
+	cmd := exec.Command("oc", "adm", "release", "info", releaseImage,
+		"-o=json",
+		"--registry-config="+pullSecretFile.Name())
+
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return nil, errors.Wrapf(err, "oc adm release info failed: %s", stderr.String())
+	}
+
+	return stdout.Bytes(), nil
+}
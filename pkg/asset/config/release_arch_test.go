@@ -0,0 +1,48 @@
+package config
+
+import "testing"
+
+func TestMatchReleaseInfoArch(t *testing.T) {
+	multiArch := releaseInfo{
+		Manifests: []struct {
+			Platform struct {
+				Architecture string `json:"architecture"`
+			} `json:"platform"`
+		}{
+			{Platform: struct {
+				Architecture string `json:"architecture"`
+			}{Architecture: "amd64"}},
+			{Platform: struct {
+				Architecture string `json:"architecture"`
+			}{Architecture: "arm64"}},
+		},
+	}
+
+	singleArch := releaseInfo{}
+	singleArch.Config.Architecture = "amd64"
+
+	cases := []struct {
+		name    string
+		info    releaseInfo
+		arch    string
+		wantErr bool
+	}{
+		{name: "multi-arch manifest list matches", info: multiArch, arch: "arm64"},
+		{name: "multi-arch manifest list does not match", info: multiArch, arch: "ppc64le", wantErr: true},
+		{name: "single-arch config matches", info: singleArch, arch: "amd64"},
+		{name: "single-arch config mismatches", info: singleArch, arch: "arm64", wantErr: true},
+		{name: "architecture undeterminable is not an error", info: releaseInfo{}, arch: "amd64"},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := matchReleaseInfoArch(tc.info, "quay.io/openshift/release@sha256:abc", tc.arch)
+			if tc.wantErr && err == nil {
+				t.Fatalf("expected an error, got none")
+			}
+			if !tc.wantErr && err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,32 @@
+package config
+
+import "testing"
+
+func TestNewReleaseResolverRequiredFields(t *testing.T) {
+	cases := []struct {
+		name    string
+		cfg     releaseResolverConfig
+		wantErr bool
+	}{
+		{name: "graph needs nothing", cfg: releaseResolverConfig{Source: ReleaseSourceGraph}},
+		{name: "mirror without mirror URL", cfg: releaseResolverConfig{Source: ReleaseSourceMirror}, wantErr: true},
+		{name: "mirror with mirror URL", cfg: releaseResolverConfig{Source: ReleaseSourceMirror, Mirror: "mirror.example.com"}},
+		{name: "oci without ociDir", cfg: releaseResolverConfig{Source: ReleaseSourceOCI}, wantErr: true},
+		{name: "oci with ociDir", cfg: releaseResolverConfig{Source: ReleaseSourceOCI, OCIDir: "/path/to/oci"}},
+		{name: "pinned without image", cfg: releaseResolverConfig{Source: ReleaseSourcePinned}, wantErr: true},
+		{name: "pinned with image", cfg: releaseResolverConfig{Source: ReleaseSourcePinned, Image: "registry.example.com/release@sha256:abc"}},
+		{name: "unsupported source", cfg: releaseResolverConfig{Source: "bogus"}, wantErr: true},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := newReleaseResolver(tc.cfg, "")
+			if tc.wantErr && err == nil {
+				t.Fatalf("expected an error, got none")
+			}
+			if !tc.wantErr && err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+		})
+	}
+}
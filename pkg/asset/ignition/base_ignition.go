@@ -0,0 +1,66 @@
+package ignition
+
+import (
+	"os"
+	"path/filepath"
+
+	igntypes "github.com/coreos/ignition/v2/config/v3_2/types"
+	"github.com/openshift/appliance/pkg/asset/config"
+	ignitionutil "github.com/openshift/appliance/pkg/ignition"
+	"github.com/openshift/installer/pkg/asset"
+	"github.com/sirupsen/logrus"
+)
+
+// BaseIgnition generates the ignition file carrying the assets that must be
+// present from the appliance's very first boot, ahead of cluster install.
+type BaseIgnition struct {
+	Config igntypes.Config
+}
+
+var _ asset.Asset = (*BaseIgnition)(nil)
+
+// Name returns the human-friendly name of the asset.
+func (i *BaseIgnition) Name() string {
+	return "Base ignition"
+}
+
+// Dependencies returns dependencies used by the asset.
+func (i *BaseIgnition) Dependencies() []asset.Asset {
+	return []asset.Asset{
+		&config.EnvConfig{},
+		&config.ApplianceConfig{},
+		&config.ProvisioningToken{},
+	}
+}
+
+// Generate the base ignition.
+func (i *BaseIgnition) Generate(dependencies asset.Parents) error {
+	envConfig := &config.EnvConfig{}
+	applianceConfig := &config.ApplianceConfig{}
+	provisioningToken := &config.ProvisioningToken{}
+	dependencies.Get(envConfig, applianceConfig, provisioningToken)
+
+	i.Config = igntypes.Config{
+		Ignition: igntypes.Ignition{
+			Version: igntypes.MaxVersion.String(),
+		},
+	}
+
+	// Carry the provisioning token as a Secret manifest. Nothing here checks
+	// it before the machine ignition is served.
+	addProvisioningTokenSecret(&i.Config, provisioningToken.Token)
+
+	logrus.Debug("Successfully generated base ignition")
+
+	return nil
+}
+
+func (i *BaseIgnition) PersistToFile(directory string) error {
+	ignition := ignitionutil.NewIgnition()
+
+	configPath := filepath.Join(directory, baseIgnitionPath)
+	if err := os.MkdirAll(filepath.Dir(configPath), os.ModePerm); err != nil {
+		return err
+	}
+	return ignition.WriteIgnitionFile(configPath, &i.Config)
+}
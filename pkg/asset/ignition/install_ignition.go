@@ -12,12 +12,14 @@ import (
 	"github.com/openshift/installer/pkg/asset"
 	assetignition "github.com/openshift/installer/pkg/asset/ignition"
 	"github.com/openshift/installer/pkg/asset/ignition/bootstrap"
+	"github.com/pkg/errors"
 	"github.com/sirupsen/logrus"
 )
 
 const (
 	InstallIgnitionPath     = "ignition/install/config.ign"
 	baseIgnitionPath        = "ignition/base/config.ign"
+	defaultUserIgnitionPath = "overrides.ign"
 	bootDevice              = "/dev/disk/by-partlabel/boot"
 	bootMountPath           = "/boot"
 	installRegistryDataPath = "/mnt/agentdata/oc-mirror/install"
@@ -35,7 +37,9 @@ var (
 
 // InstallIgnition generates the ignition file for cluster installation phase
 type InstallIgnition struct {
-	Config igntypes.Config
+	Config           igntypes.Config
+	userIgnitionPath string
+	baseIgnition     *BaseIgnition
 }
 
 var _ asset.Asset = (*InstallIgnition)(nil)
@@ -51,6 +55,7 @@ func (i *InstallIgnition) Dependencies() []asset.Asset {
 		&config.EnvConfig{},
 		&config.ApplianceConfig{},
 		&registry.RegistriesConf{},
+		&BaseIgnition{},
 	}
 }
 
@@ -59,13 +64,16 @@ func (i *InstallIgnition) Generate(dependencies asset.Parents) error {
 	envConfig := &config.EnvConfig{}
 	applianceConfig := &config.ApplianceConfig{}
 	registryConf := &registry.RegistriesConf{}
-	dependencies.Get(envConfig, applianceConfig, registryConf)
+	baseIgnition := &BaseIgnition{}
+	dependencies.Get(envConfig, applianceConfig, registryConf, baseIgnition)
+	i.baseIgnition = baseIgnition
 
 	i.Config = igntypes.Config{
 		Ignition: igntypes.Ignition{
 			Version: igntypes.MaxVersion.String(),
 		},
 	}
+	i.userIgnitionPath = applianceConfig.UserIgnitionPath
 
 	// Add public ssh key for debugging
 	// Use: export KUBECONFIG=/etc/kubernetes/bootstrap-secrets/kubeconfig
@@ -141,7 +149,12 @@ func (i *InstallIgnition) addRecoveryGrubMenuItem(tempDir string) error {
 func (i *InstallIgnition) PersistToFile(directory string) error {
 	ignition := ignitionutil.NewIgnition()
 
-	// Merge with base ignition if exists
+	// Persist the base ignition (carrying the provisioning-token Secret)
+	// so it's guaranteed to be on disk below, then merge with it.
+	if err := i.baseIgnition.PersistToFile(directory); err != nil {
+		return errors.Wrap(err, "failed to persist base ignition")
+	}
+
 	baseConfigPath := filepath.Join(directory, baseIgnitionPath)
 	baseConfig, err := ignition.ParseIgnitionFile(baseConfigPath)
 	config := &i.Config
@@ -153,9 +166,49 @@ func (i *InstallIgnition) PersistToFile(directory string) error {
 		logrus.Debugf("Merged install ignition with: %s", baseIgnitionPath)
 	}
 
+	// 3-way merge with a user-supplied ignition customization overlay, if
+	// one was configured (or the conventional overrides.ign is present).
+	userConfig, userConfigPath, err := i.loadUserIgnition(directory)
+	if err != nil {
+		return err
+	}
+	if userConfig != nil {
+		config, err = ignition.MergeIgnitionConfig(config, userConfig)
+		if err != nil {
+			return err
+		}
+		logrus.Debugf("Merged install ignition with user overlay: %s", userConfigPath)
+	}
+
 	configPath := filepath.Join(directory, InstallIgnitionPath)
 	if err := os.MkdirAll(filepath.Dir(configPath), os.ModePerm); err != nil {
 		return err
 	}
 	return ignition.WriteIgnitionFile(configPath, config)
 }
+
+// loadUserIgnition parses and validates the user ignition customization
+// overlay, preferring an explicit userIgnition path from the appliance
+// config and falling back to the conventional overrides.ign under the
+// assets dir. It returns a nil config if no overlay is configured or present.
+func (i *InstallIgnition) loadUserIgnition(directory string) (*igntypes.Config, string, error) {
+	userConfigPath := i.userIgnitionPath
+	if userConfigPath == "" {
+		userConfigPath = filepath.Join(directory, defaultUserIgnitionPath)
+		if _, err := os.Stat(userConfigPath); os.IsNotExist(err) {
+			return nil, "", nil
+		}
+	} else if !filepath.IsAbs(userConfigPath) {
+		userConfigPath = filepath.Join(directory, userConfigPath)
+	}
+
+	userConfig, err := ignitionutil.NewIgnition().ParseIgnitionFile(userConfigPath)
+	if err != nil {
+		if i.userIgnitionPath == "" && os.IsNotExist(err) {
+			return nil, "", nil
+		}
+		return nil, "", errors.Wrapf(err, "failed to parse user ignition customization file %s", userConfigPath)
+	}
+
+	return userConfig, userConfigPath, nil
+}
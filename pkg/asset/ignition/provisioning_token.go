@@ -0,0 +1,36 @@
+package ignition
+
+import (
+	"fmt"
+
+	igntypes "github.com/coreos/ignition/v2/config/v3_2/types"
+	assetignition "github.com/openshift/installer/pkg/asset/ignition"
+)
+
+const (
+	provisioningTokenSecretPath      = "/opt/openshift/openshift/99_openshift-machine-config-operator_provisioning-token-secret.yaml"
+	provisioningTokenSecretNamespace = "openshift-machine-config-operator"
+	provisioningTokenSecretName      = "provisioning-token"
+)
+
+// provisioningTokenSecretTemplate renders a Kubernetes Secret manifest
+// carrying the provisioning token, modeled after the installer's
+// ignition-provisioning-secret.yaml. Only the manifest is written; nothing
+// in this package checks the token before the machine ignition is served.
+const provisioningTokenSecretTemplate = `apiVersion: v1
+kind: Secret
+metadata:
+  name: %s
+  namespace: %s
+type: Opaque
+stringData:
+  token: %s
+`
+
+// addProvisioningTokenSecret adds the provisioning token Secret manifest to
+// the ignition config's storage files.
+func addProvisioningTokenSecret(config *igntypes.Config, token string) {
+	contents := fmt.Sprintf(provisioningTokenSecretTemplate, provisioningTokenSecretName, provisioningTokenSecretNamespace, token)
+	secretFile := assetignition.FileFromBytes(provisioningTokenSecretPath, "root", 0600, []byte(contents))
+	config.Storage.Files = append(config.Storage.Files, secretFile)
+}